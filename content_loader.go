@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"sync"
+)
+
+// ContentLoader fetches the content located at a URL. It lets windows and
+// pages load schemes other than component:// without each driver having to
+// special-case every protocol.
+type ContentLoader interface {
+	// Fetch returns the MIME type and body of the content located at u.
+	// Callers are responsible for closing body.
+	Fetch(ctx context.Context, u *url.URL) (mimeType string, body io.ReadCloser, err error)
+}
+
+var (
+	contentLoadersMutex sync.RWMutex
+	contentLoaders      = make(map[string]ContentLoader)
+)
+
+// RegisterContentLoader registers l as the ContentLoader used to fetch URLs
+// whose scheme is scheme. Registering a loader under a scheme that already
+// has one replaces it. component:// URLs are not resolved through this
+// registry: they are mounted directly from a driver's Factory, since
+// mounting a component produces a live component tree rather than a
+// content stream.
+func RegisterContentLoader(scheme string, l ContentLoader) {
+	contentLoadersMutex.Lock()
+	contentLoaders[scheme] = l
+	contentLoadersMutex.Unlock()
+}
+
+// ContentLoaderForScheme returns the ContentLoader registered for scheme,
+// and whether one was found.
+func ContentLoaderForScheme(scheme string) (ContentLoader, bool) {
+	contentLoadersMutex.RLock()
+	l, ok := contentLoaders[scheme]
+	contentLoadersMutex.RUnlock()
+	return l, ok
+}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+func init() {
+	RegisterContentLoader("file", FileContentLoader{})
+}
+
+// FileContentLoader is a ContentLoader that reads content from the local
+// filesystem.
+type FileContentLoader struct{}
+
+// Fetch satisfies the ContentLoader interface.
+func (l FileContentLoader) Fetch(ctx context.Context, u *url.URL) (mimeType string, body io.ReadCloser, err error) {
+	path := u.Path
+	if len(path) == 0 {
+		path = u.Opaque
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	mimeType = mime.TypeByExtension(filepath.Ext(path))
+	if len(mimeType) == 0 {
+		mimeType = "application/octet-stream"
+	}
+	return mimeType, f, nil
+}
@@ -0,0 +1,293 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	RegisterContentLoader("gemini", GeminiContentLoader{})
+}
+
+// geminiMaxRedirects is the number of 3x redirects followed transparently
+// before a gemini request is given up on.
+const geminiMaxRedirects = 5
+
+// GeminiContentLoader is a ContentLoader for the Gemini protocol. It
+// connects over TLS on port 1965, and converts text/gemini responses to
+// HTML so they can be handed to the existing markup engine.
+type GeminiContentLoader struct{}
+
+var (
+	geminiTrustMutex sync.Mutex
+	geminiTrust      = make(map[string][sha256.Size]byte)
+)
+
+// geminiTLSConfig returns a tls.Config implementing the trust-on-first-use
+// model mandated by the Gemini protocol: capsules are overwhelmingly
+// self-signed, so the usual CA-chain verification is skipped in favor of
+// pinning each host's certificate the first time it is seen and rejecting
+// a later connection whose certificate has silently changed.
+func geminiTLSConfig(host string) *tls.Config {
+	return &tls.Config{
+		InsecureSkipVerify: true,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errors.Errorf("no certificate presented by %s", host)
+			}
+			fingerprint := sha256.Sum256(cs.PeerCertificates[0].Raw)
+
+			geminiTrustMutex.Lock()
+			defer geminiTrustMutex.Unlock()
+
+			known, trusted := geminiTrust[host]
+			if !trusted {
+				geminiTrust[host] = fingerprint
+				return nil
+			}
+			if known != fingerprint {
+				return errors.Errorf("certificate for %s changed since it was first trusted", host)
+			}
+			return nil
+		},
+	}
+}
+
+// Fetch satisfies the ContentLoader interface.
+func (l GeminiContentLoader) Fetch(ctx context.Context, u *url.URL) (mimeType string, body io.ReadCloser, err error) {
+	return l.fetch(ctx, u, 0)
+}
+
+func (l GeminiContentLoader) fetch(ctx context.Context, u *url.URL, redirects int) (mimeType string, body io.ReadCloser, err error) {
+	if redirects > geminiMaxRedirects {
+		return "", nil, errors.Errorf("too many gemini redirects resolving %s", u)
+	}
+
+	host := u.Host
+	if len(u.Port()) == 0 {
+		host += ":1965"
+	}
+
+	d := tls.Dialer{Config: geminiTLSConfig(u.Host)}
+	conn, err := d.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "connecting to %s failed", u)
+	}
+
+	if _, err = io.WriteString(conn, u.String()+"\r\n"); err != nil {
+		conn.Close()
+		return "", nil, errors.Wrapf(err, "requesting %s failed", u)
+	}
+
+	r := bufio.NewReader(conn)
+	header, err := r.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return "", nil, errors.Wrapf(err, "reading response header for %s failed", u)
+	}
+
+	status, meta, err := parseGeminiHeader(header)
+	if err != nil {
+		conn.Close()
+		return "", nil, err
+	}
+
+	switch status[0] {
+	case '1':
+		conn.Close()
+		return "", nil, errors.Errorf("gemini input requests are not supported, requested by %s", u)
+
+	case '2':
+		mimeType = meta
+		if len(mimeType) == 0 {
+			mimeType = "text/gemini"
+		}
+
+		if !strings.HasPrefix(mimeType, "text/gemini") {
+			return mimeType, gemBody{r, conn}, nil
+		}
+
+		html, err := gemtextToHTML(r)
+		conn.Close()
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "converting %s to html failed", u)
+		}
+		return "text/html", ioutil.NopCloser(strings.NewReader(html)), nil
+
+	case '3':
+		conn.Close()
+		redirectURL, err := url.Parse(meta)
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "parsing gemini redirect from %s failed", u)
+		}
+		return l.fetch(ctx, u.ResolveReference(redirectURL), redirects+1)
+
+	case '4', '5':
+		conn.Close()
+		return "", nil, errors.Errorf("gemini request for %s failed: %s %s", u, status, meta)
+
+	case '6':
+		conn.Close()
+		return "", nil, errors.Errorf("gemini request for %s requires a client certificate", u)
+
+	default:
+		conn.Close()
+		return "", nil, errors.Errorf("unsupported gemini status %s for %s", status, u)
+	}
+}
+
+// parseGeminiHeader parses a gemini response header of the form
+// "<STATUS><SP><META>\r\n".
+func parseGeminiHeader(header string) (status, meta string, err error) {
+	header = strings.TrimRight(header, "\r\n")
+
+	parts := strings.SplitN(header, " ", 2)
+	if len(parts[0]) != 2 {
+		return "", "", errors.Errorf("malformed gemini header %q", header)
+	}
+	if _, err = strconv.Atoi(parts[0]); err != nil {
+		return "", "", errors.Errorf("malformed gemini status in header %q", header)
+	}
+
+	status = parts[0]
+	if len(parts) == 2 {
+		meta = parts[1]
+	}
+	return status, meta, nil
+}
+
+// gemBody pairs the buffered connection reader with the connection itself
+// so that closing the returned body also closes the connection.
+type gemBody struct {
+	io.Reader
+	io.Closer
+}
+
+// gemtextToHTML converts a text/gemini document into an HTML fragment,
+// line by line: "=> url [label]" becomes a link, "#"/"##"/"###" become
+// headings, a ``` line toggles a <pre> block, "* " becomes a list item and
+// "> " becomes a blockquote.
+func gemtextToHTML(r io.Reader) (string, error) {
+	scanner := bufio.NewScanner(r)
+
+	var html strings.Builder
+	inPre := false
+	inList := false
+
+	closeList := func() {
+		if inList {
+			html.WriteString("</ul>\n")
+			inList = false
+		}
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, "```") {
+			closeList()
+			if inPre {
+				html.WriteString("</pre>\n")
+			} else {
+				html.WriteString("<pre>\n")
+			}
+			inPre = !inPre
+			continue
+		}
+
+		if inPre {
+			html.WriteString(gemtextEscape(line) + "\n")
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "=>"):
+			closeList()
+			href, label := parseGeminiLink(line)
+			fmt.Fprintf(&html, "<a href=\"%s\">%s</a>\n", gemtextAttrEscape(href), gemtextEscape(label))
+
+		case strings.HasPrefix(line, "###"):
+			closeList()
+			html.WriteString("<h3>" + gemtextEscape(strings.TrimSpace(line[3:])) + "</h3>\n")
+
+		case strings.HasPrefix(line, "##"):
+			closeList()
+			html.WriteString("<h2>" + gemtextEscape(strings.TrimSpace(line[2:])) + "</h2>\n")
+
+		case strings.HasPrefix(line, "#"):
+			closeList()
+			html.WriteString("<h1>" + gemtextEscape(strings.TrimSpace(line[1:])) + "</h1>\n")
+
+		case strings.HasPrefix(line, "* "):
+			if !inList {
+				html.WriteString("<ul>\n")
+				inList = true
+			}
+			html.WriteString("<li>" + gemtextEscape(strings.TrimPrefix(line, "* ")) + "</li>\n")
+
+		case strings.HasPrefix(line, ">"):
+			closeList()
+			html.WriteString("<blockquote>" + gemtextEscape(strings.TrimSpace(strings.TrimPrefix(line, ">"))) + "</blockquote>\n")
+
+		case len(line) == 0:
+			closeList()
+			html.WriteString("<br>\n")
+
+		default:
+			closeList()
+			html.WriteString("<p>" + gemtextEscape(line) + "</p>\n")
+		}
+	}
+	closeList()
+
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return html.String(), nil
+}
+
+func parseGeminiLink(line string) (href, label string) {
+	line = strings.TrimSpace(strings.TrimPrefix(line, "=>"))
+
+	parts := strings.SplitN(line, " ", 2)
+	href = parts[0]
+	label = href
+	if len(parts) == 2 {
+		label = strings.TrimSpace(parts[1])
+	}
+	return href, label
+}
+
+var gemtextEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+)
+
+func gemtextEscape(s string) string {
+	return gemtextEscaper.Replace(s)
+}
+
+// gemtextAttrEscaper additionally escapes double quotes, making it safe to
+// use inside a double-quoted HTML attribute such as an <a> href.
+var gemtextAttrEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	"\"", "&quot;",
+)
+
+func gemtextAttrEscape(s string) string {
+	return gemtextAttrEscaper.Replace(s)
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseGeminiHeaderSuccess(t *testing.T) {
+	status, meta, err := parseGeminiHeader("20 text/gemini\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != "20" {
+		t.Fatalf("unexpected status: %q", status)
+	}
+	if meta != "text/gemini" {
+		t.Fatalf("unexpected meta: %q", meta)
+	}
+}
+
+func TestParseGeminiHeaderRedirect(t *testing.T) {
+	status, meta, err := parseGeminiHeader("30 /new/path\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != "30" {
+		t.Fatalf("unexpected status: %q", status)
+	}
+	if meta != "/new/path" {
+		t.Fatalf("unexpected meta: %q", meta)
+	}
+}
+
+func TestParseGeminiHeaderMalformed(t *testing.T) {
+	if _, _, err := parseGeminiHeader("not a header"); err == nil {
+		t.Fatal("expected an error for a malformed header")
+	}
+}
+
+func TestGemtextToHTML(t *testing.T) {
+	input := "# Title\n" +
+		"Some text\n" +
+		"=> gemini://example.org/ An example\n" +
+		"* item one\n" +
+		"* item two\n" +
+		"> a quote\n" +
+		"```\n" +
+		"code <line>\n" +
+		"```\n"
+
+	html, err := gemtextToHTML(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"<h1>Title</h1>",
+		"<p>Some text</p>",
+		`<a href="gemini://example.org/">An example</a>`,
+		"<li>item one</li>",
+		"<li>item two</li>",
+		"<blockquote>a quote</blockquote>",
+		"<pre>",
+		"code &lt;line&gt;",
+		"</pre>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("expected html to contain %q, got:\n%s", want, html)
+		}
+	}
+}
+
+func TestGemtextLinkHrefIsHTMLAttributeEscaped(t *testing.T) {
+	html, err := gemtextToHTML(strings.NewReader(`=> gemini://example.org/"><script>alert(1)</script> evil`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(html, "<script>") {
+		t.Fatalf("href should have been html-escaped, not emit a literal tag, got:\n%s", html)
+	}
+	if !strings.Contains(html, "&quot;") {
+		t.Fatalf("expected the quote in href to be escaped as &quot;, got:\n%s", html)
+	}
+}
+
+func TestParseGeminiLinkWithoutLabel(t *testing.T) {
+	href, label := parseGeminiLink("=> gemini://example.org/")
+	if href != "gemini://example.org/" {
+		t.Fatalf("unexpected href: %q", href)
+	}
+	if label != href {
+		t.Fatalf("expected label to default to href, got %q", label)
+	}
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+)
+
+func init() {
+	RegisterContentLoader("https", HTTPSContentLoader{})
+}
+
+// HTTPSContentLoader is a ContentLoader that fetches content over HTTPS.
+type HTTPSContentLoader struct{}
+
+// Fetch satisfies the ContentLoader interface.
+func (l HTTPSContentLoader) Fetch(ctx context.Context, u *url.URL) (mimeType string, body io.ReadCloser, err error) {
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "requesting %s failed", u)
+	}
+
+	res, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "fetching %s failed", u)
+	}
+
+	if res.StatusCode >= 400 {
+		res.Body.Close()
+		return "", nil, errors.Errorf("fetching %s failed: %s", u, res.Status)
+	}
+
+	mimeType, _, err = mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if err != nil {
+		mimeType = "application/octet-stream"
+	}
+	return mimeType, res.Body, nil
+}
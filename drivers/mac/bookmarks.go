@@ -0,0 +1,174 @@
+// +build darwin,amd64
+
+package mac
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/murlokswarm/app/bridge"
+	"github.com/pkg/errors"
+)
+
+// Bookmark is a component URL persisted by the user for quick access from
+// the Bookmarks menu.
+type Bookmark struct {
+	ID    uuid.UUID `json:"id"`
+	Title string    `json:"title"`
+	URL   string    `json:"url"`
+}
+
+// AddBookmark saves a bookmark pointing to rawurl under the given title and
+// returns it.
+func (d *Driver) AddBookmark(title, rawurl string) (Bookmark, error) {
+	if _, err := url.Parse(rawurl); err != nil {
+		return Bookmark{}, errors.Wrap(err, "parsing bookmark url failed")
+	}
+
+	b := Bookmark{
+		ID:    uuid.New(),
+		Title: title,
+		URL:   rawurl,
+	}
+
+	d.bookmarksMutex.Lock()
+	d.bookmarks = append(d.bookmarks, b)
+	bookmarks := d.bookmarks
+	d.bookmarksMutex.Unlock()
+
+	if err := d.saveBookmarks(bookmarks); err != nil {
+		return Bookmark{}, err
+	}
+	return b, nil
+}
+
+// RemoveBookmark deletes the bookmark identified by id.
+func (d *Driver) RemoveBookmark(id uuid.UUID) error {
+	d.bookmarksMutex.Lock()
+	bookmarks := make([]Bookmark, 0, len(d.bookmarks))
+	for _, b := range d.bookmarks {
+		if b.ID != id {
+			bookmarks = append(bookmarks, b)
+		}
+	}
+	d.bookmarks = bookmarks
+	d.bookmarksMutex.Unlock()
+
+	return d.saveBookmarks(bookmarks)
+}
+
+// Bookmarks returns the currently persisted bookmarks.
+func (d *Driver) Bookmarks() []Bookmark {
+	d.bookmarksMutex.Lock()
+	defer d.bookmarksMutex.Unlock()
+
+	bookmarks := make([]Bookmark, len(d.bookmarks))
+	copy(bookmarks, d.bookmarks)
+	return bookmarks
+}
+
+// loadBookmarks reads the bookmark store from disk. A missing store is not
+// an error: it just means no bookmark has been added yet.
+func (d *Driver) loadBookmarks() error {
+	filename := d.Storage("bookmarks.json")
+
+	b, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "loading bookmarks failed")
+	}
+
+	var bookmarks []Bookmark
+	if err = json.Unmarshal(b, &bookmarks); err != nil {
+		return errors.Wrap(err, "loading bookmarks failed")
+	}
+
+	d.bookmarksMutex.Lock()
+	d.bookmarks = bookmarks
+	d.bookmarksMutex.Unlock()
+	return nil
+}
+
+func (d *Driver) saveBookmarks(bookmarks []Bookmark) error {
+	filename := d.Storage("bookmarks.json")
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return errors.Wrap(err, "saving bookmarks failed")
+	}
+
+	b, err := json.Marshal(bookmarks)
+	if err != nil {
+		return errors.Wrap(err, "saving bookmarks failed")
+	}
+
+	if err = ioutil.WriteFile(filename, b, 0644); err != nil {
+		return errors.Wrap(err, "saving bookmarks failed")
+	}
+
+	if err = d.refreshBookmarksMenu(bookmarks); err != nil {
+		return err
+	}
+
+	if d.OnBookmarksChange != nil {
+		d.OnBookmarksChange(bookmarks)
+	}
+	return nil
+}
+
+// refreshBookmarksMenu rebuilds the "Bookmarks" submenu in the menu bar from
+// the current bookmark store.
+func (d *Driver) refreshBookmarksMenu(bookmarks []Bookmark) error {
+	if d.menubar == nil {
+		return nil
+	}
+
+	_, err := d.macos.Request(
+		"/driver/menubar/bookmarks/set",
+		bridge.NewPayload(bookmarks),
+	)
+	return errors.Wrap(err, "refreshing bookmarks menu failed")
+}
+
+// onBookmarkOpen is called when a bookmark is clicked from the Bookmarks
+// menu. It loads the bookmarked URL in the window most recently created with
+// NewWindow; pages are never targeted, and focusing another window does not
+// change which window is used.
+func (d *Driver) onBookmarkOpen(u *url.URL, p bridge.Payload) (res bridge.Payload) {
+	var id uuid.UUID
+	p.Unmarshal(&id)
+
+	d.bookmarksMutex.Lock()
+	var bookmark Bookmark
+	for _, b := range d.bookmarks {
+		if b.ID == id {
+			bookmark = b
+			break
+		}
+	}
+	d.bookmarksMutex.Unlock()
+
+	if len(bookmark.URL) == 0 {
+		panic(errors.Errorf("bookmark %v not found", id))
+	}
+
+	if d.frontmost != nil {
+		if err := d.frontmost.Load(bookmark.URL); err != nil {
+			panic(errors.Wrap(err, "opening bookmark failed"))
+		}
+	}
+
+	bookmarkURL, err := url.Parse(bookmark.URL)
+	if err != nil {
+		panic(errors.Wrap(err, "opening bookmark failed"))
+	}
+
+	if d.OnBookmarkOpen != nil {
+		d.OnBookmarkOpen(bookmarkURL)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+// +build darwin,amd64
+
+package mac
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	"github.com/murlokswarm/app"
+	"github.com/pkg/errors"
+)
+
+// loadForeignContent resolves rawurl through the app.ContentLoader
+// registered for its scheme. ok is false when rawurl has no registered
+// scheme (notably component://), in which case the caller should fall
+// through to the regular Factory-based loading.
+func loadForeignContent(rawurl string) (compo app.Component, ok bool, err error) {
+	if len(rawurl) == 0 {
+		return nil, false, nil
+	}
+
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "parsing %s failed", rawurl)
+	}
+
+	loader, ok := app.ContentLoaderForScheme(u.Scheme)
+	if !ok {
+		return nil, false, nil
+	}
+
+	mimeType, body, err := loader.Fetch(context.Background(), u)
+	if err != nil {
+		return nil, true, errors.Wrapf(err, "loading %s failed", u)
+	}
+	defer body.Close()
+
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, true, errors.Wrapf(err, "loading %s failed", u)
+	}
+
+	if !strings.HasPrefix(mimeType, "text/html") && !strings.HasPrefix(mimeType, "text/plain") {
+		return nil, true, errors.Errorf("loading %s failed: unsupported mime type %q", u, mimeType)
+	}
+	return &app.RawContent{HTML: string(b)}, true, nil
+}
@@ -18,6 +18,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/murlokswarm/app"
 	"github.com/murlokswarm/app/bridge"
@@ -38,6 +39,16 @@ type Driver struct {
 	// Component url to load in the dock.
 	DockURL string
 
+	// Session auto-restore configuration.
+	SessionConfig SessionConfig
+
+	// The runtime mode the driver starts in. Defaults to app.ModePublic.
+	Mode app.Mode
+
+	// The component url loaded in place of one restricted to the wrong
+	// mode.
+	FallbackURL string
+
 	// The handler called when the app is running.
 	OnRun func()
 
@@ -62,16 +73,53 @@ type Driver struct {
 	// The handler called when the app is about to exit.
 	OnExit func()
 
-	factory      app.Factory
-	elements     app.ElemDB
-	uichan       chan func()
-	cancel       func()
-	macos        bridge.PlatformBridge
-	golang       bridge.GoBridge
-	menubar      app.Menu
-	dock         app.DockTile
-	devID        string
-	droppedFiles []string
+	// The handler called when a bookmark is opened from the Bookmarks menu.
+	OnBookmarkOpen func(u *url.URL)
+
+	// The handler called every time the bookmark store changes.
+	OnBookmarksChange func(bookmarks []Bookmark)
+
+	// The handler called after each pending storage migration completes.
+	OnMigrationProgress func(step, total int)
+
+	factory        app.Factory
+	elements       app.ElemDB
+	uichan         chan func()
+	cancel         func()
+	macos          bridge.PlatformBridge
+	golang         bridge.GoBridge
+	menubar        app.Menu
+	dock           app.DockTile
+	devID          string
+	droppedFiles   []string
+	bookmarks      []Bookmark
+	bookmarksMutex sync.Mutex
+	frontmost      frontmostLoader
+	mode           app.Mode
+	modeMutex      sync.Mutex
+	migrator       app.Migrator
+}
+
+// SessionConfig configures a driver's automatic session restore.
+type SessionConfig struct {
+	// AutoRestore restores the named session right after the driver starts
+	// running.
+	AutoRestore bool
+
+	// Name is the session to auto restore. Defaults to "last".
+	Name string
+}
+
+// RegisterMigration registers a migration step to run against the app
+// support directory the next time the driver runs.
+func (d *Driver) RegisterMigration(m app.Migration) {
+	d.migrator.Register(m)
+}
+
+// frontmostLoader is satisfied by app.Window and app.Page. NewWindow stores
+// each window it creates in it, so bookmarks open in that window.
+type frontmostLoader interface {
+	Load(rawurl string, v ...interface{}) error
 }
 
 // Name satisfies the app.Driver interface.
@@ -91,7 +139,8 @@ func (d *Driver) Run(f app.Factory) error {
 	}
 
 	d.devID = generateDevID()
-	d.factory = f
+	d.mode = d.Mode
+	d.factory = app.NewModeFactory(f, d.currentMode, d.FallbackURL)
 
 	elements := app.NewElemDB()
 	elements = app.NewConcurrentElemDB(elements)
@@ -112,6 +161,7 @@ func (d *Driver) Run(f app.Factory) error {
 	d.golang.Handle("/driver/filedrop", d.onFileDrop)
 	d.golang.Handle("/driver/quit", d.onQuit)
 	d.golang.Handle("/driver/exit", d.onExit)
+	d.golang.Handle("/driver/bookmark/open", d.onBookmarkOpen)
 
 	d.golang.Handle("/window/move", windowHandler(onWindowMove))
 	d.golang.Handle("/window/resize", windowHandler(onWindowResize))
@@ -133,6 +183,16 @@ func (d *Driver) Run(f app.Factory) error {
 
 	d.golang.Handle("/notification/reply", notificationHandler(onNotificationReply))
 
+	support, err := d.support()
+	if err != nil {
+		return err
+	}
+
+	d.migrator.OnMigrationProgress = d.OnMigrationProgress
+	if err = d.migrator.Run(filepath.Join(support, "storage")); err != nil {
+		return errors.Wrap(err, "migrating app support directory failed")
+	}
+
 	var ctx context.Context
 	ctx, d.cancel = context.WithCancel(context.Background())
 	defer d.cancel()
@@ -157,17 +217,36 @@ func (d *Driver) Run(f app.Factory) error {
 }
 
 func (d *Driver) onRun(u *url.URL, p bridge.Payload) (res bridge.Payload) {
+	if err := d.loadBookmarks(); err != nil {
+		panic(err)
+	}
+
 	err := d.newMenuBar()
 	if err != nil {
 		panic(err)
 	}
 
+	// newDockTile (dock.go) is not part of this change: it lives outside
+	// the files touched by the content-loader refactor, so DockURL is not
+	// yet routed through the ContentLoader registry the way NewWindow and
+	// test.page.load are.
 	if d.dock, err = newDockTile(app.MenuConfig{
 		DefaultURL: d.DockURL,
 	}); err != nil {
 		panic(err)
 	}
 
+	if d.SessionConfig.AutoRestore {
+		name := d.SessionConfig.Name
+		if len(name) == 0 {
+			name = "last"
+		}
+
+		if err = d.RestoreSession(name); err != nil && !os.IsNotExist(err) {
+			panic(err)
+		}
+	}
+
 	if d.OnRun != nil {
 		d.OnRun()
 	}
@@ -310,9 +389,60 @@ func (d *Driver) support() (dirname string, err error) {
 	return dirname, nil
 }
 
-// NewWindow satisfies the app.DriverWithWindows interface.
+// SetMode switches the driver to the given runtime mode. Components tagged
+// for the other mode will be redirected to FallbackURL the next time they
+// are loaded.
+func (d *Driver) SetMode(m app.Mode) {
+	d.modeMutex.Lock()
+	d.mode = m
+	d.modeMutex.Unlock()
+}
+
+func (d *Driver) currentMode() app.Mode {
+	d.modeMutex.Lock()
+	defer d.modeMutex.Unlock()
+	return d.mode
+}
+
+// PrivateOnly tags name so it is only ever mounted while the driver runs
+// in ModePrivate, redirecting to FallbackURL otherwise.
+func (d *Driver) PrivateOnly(name string) {
+	if f, ok := d.factory.(*app.ModeFactory); ok {
+		f.PrivateOnly(name)
+	}
+}
+
+// PublicOnly tags name so it is only ever mounted while the driver runs in
+// ModePublic, redirecting to FallbackURL otherwise.
+func (d *Driver) PublicOnly(name string) {
+	if f, ok := d.factory.(*app.ModeFactory); ok {
+		f.PublicOnly(name)
+	}
+}
+
+// NewWindow satisfies the app.DriverWithWindows interface. When c.DefaultURL
+// uses a scheme registered with a ContentLoader (file, https, gemini...),
+// the fetched content is mounted instead of routing through the regular
+// component Factory.
 func (d *Driver) NewWindow(c app.WindowConfig) (app.Window, error) {
-	return newWindow(c)
+	compo, handled, err := loadForeignContent(c.DefaultURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if handled {
+		original := d.factory
+		d.factory = app.StaticFactory{Component: compo}
+		defer func() { d.factory = original }()
+	}
+
+	w, err := newWindow(c)
+	if err != nil {
+		return nil, err
+	}
+
+	d.frontmost = w
+	return w, nil
 }
 
 // NewContextMenu satisfies the app.Driver interface.
@@ -423,7 +553,7 @@ func (d *Driver) newMenuBar() error {
 	); err != nil {
 		return errors.Wrap(err, "set the menu bar failed")
 	}
-	return nil
+	return d.refreshBookmarksMenu(d.Bookmarks())
 }
 
 // Dock satisfies the app.DriverWithDock interface.
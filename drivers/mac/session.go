@@ -0,0 +1,149 @@
+// +build darwin,amd64
+
+package mac
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/murlokswarm/app"
+	"github.com/pkg/errors"
+)
+
+// WindowSession is the persisted state of a single window: its bounds,
+// fullscreen state and navigation history.
+type WindowSession struct {
+	X          float64  `json:"x"`
+	Y          float64  `json:"y"`
+	Width      float64  `json:"width"`
+	Height     float64  `json:"height"`
+	FullScreen bool     `json:"full_screen"`
+	PriorURLs  []string `json:"prior_urls"`
+	URL        string   `json:"url"`
+	NextURLs   []string `json:"next_urls"`
+}
+
+// Session is the state of every live window, captured by SaveSession and
+// replayed by RestoreSession.
+type Session struct {
+	Windows      []WindowSession `json:"windows"`
+	DroppedFiles []string        `json:"dropped_files"`
+}
+
+// sessionWindow is satisfied by the windows a Driver can snapshot and
+// restore.
+type sessionWindow interface {
+	frontmostLoader
+
+	Bounds() (x, y, width, height float64)
+	IsFullScreen() bool
+	History() app.History
+}
+
+// SaveSession captures the bounds, fullscreen state, navigation history and
+// dropped-file context of every live window under name, so it can later be
+// restored with RestoreSession.
+func (d *Driver) SaveSession(name string) error {
+	session := Session{DroppedFiles: d.droppedFiles}
+
+	for _, e := range d.elements.Elements() {
+		sw, ok := e.(sessionWindow)
+		if !ok {
+			continue
+		}
+
+		x, y, width, height := sw.Bounds()
+		prior, current, next, err := app.SnapshotHistory(sw.History())
+		if err != nil {
+			return errors.Wrap(err, "saving session failed")
+		}
+
+		session.Windows = append(session.Windows, WindowSession{
+			X:          x,
+			Y:          y,
+			Width:      width,
+			Height:     height,
+			FullScreen: sw.IsFullScreen(),
+			PriorURLs:  prior,
+			URL:        current,
+			NextURLs:   next,
+		})
+	}
+
+	return d.writeSession(name, session)
+}
+
+// RestoreSession recreates every window recorded in the session named name,
+// replaying its navigation history and loading its current URL.
+func (d *Driver) RestoreSession(name string) error {
+	session, err := d.readSession(name)
+	if err != nil {
+		return err
+	}
+
+	for _, ws := range session.Windows {
+		w, err := d.NewWindow(app.WindowConfig{
+			X:          ws.X,
+			Y:          ws.Y,
+			Width:      ws.Width,
+			Height:     ws.Height,
+			FullScreen: ws.FullScreen,
+		})
+		if err != nil {
+			return errors.Wrap(err, "restoring session failed")
+		}
+
+		if sw, ok := w.(sessionWindow); ok {
+			history := sw.History()
+			for _, u := range ws.PriorURLs {
+				history.NewEntry(u)
+			}
+			if len(ws.URL) != 0 {
+				history.NewEntry(ws.URL)
+			}
+			for _, u := range ws.NextURLs {
+				history.NewEntry(u)
+			}
+			for range ws.NextURLs {
+				history.Previous()
+			}
+		}
+
+		if len(ws.URL) != 0 {
+			if err = w.Load(ws.URL); err != nil {
+				return errors.Wrap(err, "restoring session failed")
+			}
+		}
+	}
+
+	d.droppedFiles = session.DroppedFiles
+	return nil
+}
+
+func (d *Driver) readSession(name string) (Session, error) {
+	filename := d.Storage("sessions", name+".json")
+
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return Session{}, err
+	}
+
+	var session Session
+	err = json.Unmarshal(b, &session)
+	return session, err
+}
+
+func (d *Driver) writeSession(name string, session Session) error {
+	filename := d.Storage("sessions", name+".json")
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return errors.Wrap(err, "saving session failed")
+	}
+
+	b, err := json.Marshal(session)
+	if err != nil {
+		return errors.Wrap(err, "saving session failed")
+	}
+	return ioutil.WriteFile(filename, b, 0644)
+}
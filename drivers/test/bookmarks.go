@@ -0,0 +1,119 @@
+package test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// Bookmark is a component URL persisted by the user for quick access.
+type Bookmark struct {
+	ID    uuid.UUID `json:"id"`
+	Title string    `json:"title"`
+	URL   string    `json:"url"`
+}
+
+// AddBookmark saves a bookmark pointing to rawurl under the given title and
+// returns it.
+func (d *Driver) AddBookmark(title, rawurl string) (Bookmark, error) {
+	if _, err := url.Parse(rawurl); err != nil {
+		return Bookmark{}, errors.Wrap(err, "parsing bookmark url failed")
+	}
+
+	b := Bookmark{
+		ID:    uuid.New(),
+		Title: title,
+		URL:   rawurl,
+	}
+
+	d.bookmarksMutex.Lock()
+	d.bookmarks = append(d.bookmarks, b)
+	bookmarks := d.bookmarks
+	d.bookmarksMutex.Unlock()
+
+	return b, d.saveBookmarks(bookmarks)
+}
+
+// RemoveBookmark deletes the bookmark identified by id.
+func (d *Driver) RemoveBookmark(id uuid.UUID) error {
+	d.bookmarksMutex.Lock()
+	bookmarks := make([]Bookmark, 0, len(d.bookmarks))
+	for _, b := range d.bookmarks {
+		if b.ID != id {
+			bookmarks = append(bookmarks, b)
+		}
+	}
+	d.bookmarks = bookmarks
+	d.bookmarksMutex.Unlock()
+
+	return d.saveBookmarks(bookmarks)
+}
+
+// Bookmarks returns the currently persisted bookmarks.
+func (d *Driver) Bookmarks() []Bookmark {
+	d.bookmarksMutex.Lock()
+	defer d.bookmarksMutex.Unlock()
+
+	bookmarks := make([]Bookmark, len(d.bookmarks))
+	copy(bookmarks, d.bookmarks)
+	return bookmarks
+}
+
+func (d *Driver) loadBookmarks() error {
+	filename := d.Storage("bookmarks.json")
+
+	b, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "loading bookmarks failed")
+	}
+
+	var bookmarks []Bookmark
+	if err = json.Unmarshal(b, &bookmarks); err != nil {
+		return errors.Wrap(err, "loading bookmarks failed")
+	}
+
+	d.bookmarksMutex.Lock()
+	d.bookmarks = bookmarks
+	d.bookmarksMutex.Unlock()
+	return nil
+}
+
+func (d *Driver) saveBookmarks(bookmarks []Bookmark) error {
+	filename := d.Storage("bookmarks.json")
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return errors.Wrap(err, "saving bookmarks failed")
+	}
+
+	b, err := json.Marshal(bookmarks)
+	if err != nil {
+		return errors.Wrap(err, "saving bookmarks failed")
+	}
+	return ioutil.WriteFile(filename, b, 0644)
+}
+
+// OnBookmarkOpen loads the bookmarked URL identified by id in the driver's
+// default page, mimicking a click on a bookmark in the mac driver's menu.
+func (d *Driver) OnBookmarkOpen(id uuid.UUID) error {
+	d.bookmarksMutex.Lock()
+	var bookmark Bookmark
+	for _, b := range d.bookmarks {
+		if b.ID == id {
+			bookmark = b
+			break
+		}
+	}
+	d.bookmarksMutex.Unlock()
+
+	if len(bookmark.URL) == 0 {
+		return errors.Errorf("bookmark %v not found", id)
+	}
+	return d.page.Load(bookmark.URL)
+}
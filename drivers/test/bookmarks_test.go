@@ -0,0 +1,94 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func newBookmarksTestDriver(t *testing.T) *Driver {
+	t.Helper()
+
+	support, err := ioutil.TempDir("", "app-test-driver-bookmarks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Driver{support: support}
+}
+
+func TestAddBookmarkPersistsIt(t *testing.T) {
+	d := newBookmarksTestDriver(t)
+	defer os.RemoveAll(d.support)
+
+	b, err := d.AddBookmark("Example", "https://example.org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Title != "Example" || b.URL != "https://example.org" {
+		t.Fatalf("unexpected bookmark: %+v", b)
+	}
+
+	reloaded := &Driver{support: d.support}
+	if err = reloaded.loadBookmarks(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(reloaded.Bookmarks(), d.Bookmarks()) {
+		t.Fatalf("expected reloaded bookmarks %+v to match saved ones %+v", reloaded.Bookmarks(), d.Bookmarks())
+	}
+}
+
+func TestAddBookmarkRejectsMalformedURL(t *testing.T) {
+	d := newBookmarksTestDriver(t)
+	defer os.RemoveAll(d.support)
+
+	if _, err := d.AddBookmark("Bad", "://not-a-url"); err == nil {
+		t.Fatal("expected an error for a malformed url")
+	}
+	if len(d.Bookmarks()) != 0 {
+		t.Fatalf("expected no bookmark to be stored, got %+v", d.Bookmarks())
+	}
+}
+
+func TestRemoveBookmarkDropsOnlyTheMatchingID(t *testing.T) {
+	d := newBookmarksTestDriver(t)
+	defer os.RemoveAll(d.support)
+
+	first, err := d.AddBookmark("First", "https://example.org/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := d.AddBookmark("Second", "https://example.org/2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = d.RemoveBookmark(first.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	bookmarks := d.Bookmarks()
+	if len(bookmarks) != 1 || bookmarks[0].ID != second.ID {
+		t.Fatalf("expected only %v to remain, got %+v", second.ID, bookmarks)
+	}
+
+	reloaded := &Driver{support: d.support}
+	if err = reloaded.loadBookmarks(); err != nil {
+		t.Fatal(err)
+	}
+	if reloadedBookmarks := reloaded.Bookmarks(); len(reloadedBookmarks) != 1 || reloadedBookmarks[0].ID != second.ID {
+		t.Fatalf("expected the removal to be persisted, got %+v", reloadedBookmarks)
+	}
+}
+
+func TestLoadBookmarksMissingStoreIsNotAnError(t *testing.T) {
+	d := newBookmarksTestDriver(t)
+	defer os.RemoveAll(d.support)
+
+	if err := d.loadBookmarks(); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Bookmarks()) != 0 {
+		t.Fatalf("expected no bookmarks, got %+v", d.Bookmarks())
+	}
+}
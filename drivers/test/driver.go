@@ -0,0 +1,99 @@
+// Package test is a driver that runs components without any graphical
+// backend. It is meant to be used in tests.
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/murlokswarm/app"
+)
+
+// Driver is the app.Driver implementation for tests.
+type Driver struct {
+	app.BaseDriver
+
+	// Component url to load in the default page.
+	DefaultURL string
+
+	factory        app.Factory
+	elements       app.ElemDB
+	support        string
+	page           app.Page
+	bookmarks      []Bookmark
+	bookmarksMutex sync.Mutex
+}
+
+// Name satisfies the app.Driver interface.
+func (d *Driver) Name() string {
+	return "Test"
+}
+
+// Base satisfies the app.Driver interface.
+func (d *Driver) Base() app.Driver {
+	return d
+}
+
+// Run satisfies the app.Driver interface.
+func (d *Driver) Run(f app.Factory) error {
+	d.factory = f
+
+	elements := app.NewElemDB()
+	elements = app.NewConcurrentElemDB(elements)
+	d.elements = elements
+
+	support, err := ioutil.TempDir("", "app-test-driver")
+	if err != nil {
+		return err
+	}
+	d.support = support
+
+	if err = d.loadBookmarks(); err != nil {
+		return err
+	}
+
+	page, err := newPage(d, app.PageConfig{DefaultURL: d.DefaultURL})
+	if err != nil {
+		return err
+	}
+	d.page = page
+	return nil
+}
+
+// NewPage creates a new page running the given configuration.
+func (d *Driver) NewPage(c app.PageConfig) (app.Page, error) {
+	return newPage(d, c)
+}
+
+// Render satisfies the app.Driver interface.
+func (d *Driver) Render(c app.Component) error {
+	e, err := d.elements.ElementByComponent(c)
+	if err != nil {
+		return err
+	}
+	return e.Render(c)
+}
+
+// ElementByComponent satisfies the app.Driver interface.
+func (d *Driver) ElementByComponent(c app.Component) (app.ElementWithComponent, error) {
+	return d.elements.ElementByComponent(c)
+}
+
+// Storage satisfies the app.DriverWithStorage interface.
+func (d *Driver) Storage(path ...string) string {
+	storage := append([]string{d.support}, "storage")
+	storage = append(storage, path...)
+	return filepath.Join(storage...)
+}
+
+// CallOnUIGoroutine satisfies the app.Driver interface.
+func (d *Driver) CallOnUIGoroutine(f func()) {
+	f()
+}
+
+// Close removes the temporary support directory used by the driver.
+func (d *Driver) Close() {
+	os.RemoveAll(d.support)
+}
@@ -1,8 +1,11 @@
 package test
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -91,7 +94,7 @@ func (p *page) load(u *url.URL) error {
 		p.markup.Dismount(p.component)
 	}
 
-	compo, err := p.factory.New(app.ComponentNameFromURL(u))
+	compo, err := p.loadComponent(u)
 	if err != nil {
 		return err
 	}
@@ -104,6 +107,32 @@ func (p *page) load(u *url.URL) error {
 	return nil
 }
 
+// loadComponent resolves u through the ContentLoader registered for u's
+// scheme, or through the page's Factory when no loader is registered for it
+// (notably component:// URLs).
+func (p *page) loadComponent(u *url.URL) (app.Component, error) {
+	loader, ok := app.ContentLoaderForScheme(u.Scheme)
+	if !ok {
+		return p.factory.New(app.ComponentNameFromURL(u))
+	}
+
+	mimeType, body, err := loader.Fetch(context.Background(), u)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading %s in test page %p failed", u, p)
+	}
+	defer body.Close()
+
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "loading %s in test page %p failed", u, p)
+	}
+
+	if !strings.HasPrefix(mimeType, "text/html") && !strings.HasPrefix(mimeType, "text/plain") {
+		return nil, errors.Errorf("loading %s in test page %p failed: unsupported mime type %q", u, p, mimeType)
+	}
+	return &app.RawContent{HTML: string(b)}, nil
+}
+
 // Render satisfies the app.Page interface.
 func (p *page) Render(compo app.Component) error {
 	_, err := p.markup.Update(compo)
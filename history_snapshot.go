@@ -0,0 +1,44 @@
+package app
+
+// SnapshotHistory returns h's back-stack and forward-stack, both in
+// chronological order, together with its current URL, restoring h's
+// cursor to its original position. It is used by drivers to persist a
+// History across a save/restore cycle without the History interface
+// needing an enumeration method of its own.
+func SnapshotHistory(h History) (prior []string, current string, next []string, err error) {
+	if current, err = h.Current(); err != nil {
+		return nil, "", nil, err
+	}
+
+	steps := 0
+	for h.CanPrevious() {
+		u, err := h.Previous()
+		if err != nil {
+			return nil, "", nil, err
+		}
+		prior = append(prior, u)
+		steps++
+	}
+	for i := 0; i < steps; i++ {
+		h.Next()
+	}
+
+	for i, j := 0, len(prior)-1; i < j; i, j = i+1, j-1 {
+		prior[i], prior[j] = prior[j], prior[i]
+	}
+
+	steps = 0
+	for h.CanNext() {
+		u, err := h.Next()
+		if err != nil {
+			return nil, "", nil, err
+		}
+		next = append(next, u)
+		steps++
+	}
+	for i := 0; i < steps; i++ {
+		h.Previous()
+	}
+
+	return prior, current, next, nil
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+// fakeHistory is a minimal, in-memory app.History used to exercise
+// SnapshotHistory without any driver dependency.
+type fakeHistory struct {
+	entries []string
+	cursor  int
+}
+
+func newFakeHistory(entries ...string) *fakeHistory {
+	return &fakeHistory{entries: entries, cursor: len(entries) - 1}
+}
+
+func (h *fakeHistory) NewEntry(url string) {
+	h.entries = append(h.entries[:h.cursor+1], url)
+	h.cursor = len(h.entries) - 1
+}
+
+func (h *fakeHistory) Current() (string, error) {
+	return h.entries[h.cursor], nil
+}
+
+func (h *fakeHistory) CanPrevious() bool {
+	return h.cursor > 0
+}
+
+func (h *fakeHistory) Previous() (string, error) {
+	h.cursor--
+	return h.entries[h.cursor], nil
+}
+
+func (h *fakeHistory) CanNext() bool {
+	return h.cursor < len(h.entries)-1
+}
+
+func (h *fakeHistory) Next() (string, error) {
+	h.cursor++
+	return h.entries[h.cursor], nil
+}
+
+func TestSnapshotHistoryAtEndOfStack(t *testing.T) {
+	h := newFakeHistory("/a", "/b", "/c")
+
+	prior, current, next, err := SnapshotHistory(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(prior, []string{"/a", "/b"}) {
+		t.Fatalf("unexpected prior: %v", prior)
+	}
+	if current != "/c" {
+		t.Fatalf("unexpected current: %q", current)
+	}
+	if len(next) != 0 {
+		t.Fatalf("unexpected next: %v", next)
+	}
+}
+
+func TestSnapshotHistoryInTheMiddleOfTheStack(t *testing.T) {
+	h := newFakeHistory("/a", "/b", "/c")
+	h.cursor = 1
+
+	prior, current, next, err := SnapshotHistory(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(prior, []string{"/a"}) {
+		t.Fatalf("unexpected prior: %v", prior)
+	}
+	if current != "/b" {
+		t.Fatalf("unexpected current: %q", current)
+	}
+	if !reflect.DeepEqual(next, []string{"/c"}) {
+		t.Fatalf("unexpected next: %v", next)
+	}
+}
+
+func TestSnapshotHistoryRestoresCursor(t *testing.T) {
+	h := newFakeHistory("/a", "/b", "/c")
+	h.cursor = 1
+
+	if _, _, _, err := SnapshotHistory(h); err != nil {
+		t.Fatal(err)
+	}
+
+	current, err := h.Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current != "/b" {
+		t.Fatalf("expected cursor to be restored to /b, got %q", current)
+	}
+	if !h.CanNext() {
+		t.Fatal("expected forward history to still be reachable after the snapshot")
+	}
+}
@@ -0,0 +1,211 @@
+package app
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// migrationsFilename is the name of the file, inside a storage directory,
+// that records the storage directory's current version.
+const migrationsFilename = "_migrations.json"
+
+// Migration is a single step that upgrades the content of a storage
+// directory from FromVersion to ToVersion.
+type Migration struct {
+	FromVersion string
+	ToVersion   string
+	Up          func(storageDir string) error
+}
+
+// Migrator runs the ordered list of registered Migrations needed to bring a
+// storage directory from its recorded version up to the latest one.
+type Migrator struct {
+	// OnMigrationProgress is called after each migration step completes,
+	// with step being the 1-based index of the step just run and total the
+	// number of steps being applied.
+	OnMigrationProgress func(step, total int)
+
+	migrations []Migration
+}
+
+// Register appends migration to the ordered list of migrations to run.
+func (m *Migrator) Register(migration Migration) {
+	m.migrations = append(m.migrations, migration)
+}
+
+type migratorState struct {
+	Version string `json:"version"`
+}
+
+// Run executes, in order, the pending migrations needed to bring
+// storageDir up to date. Each step is staged in a temporary directory next
+// to storageDir and swapped in with os.Rename only once it succeeds, so a
+// failing migration leaves storageDir untouched.
+func (m *Migrator) Run(storageDir string) error {
+	versionsFile := filepath.Join(storageDir, migrationsFilename)
+
+	state, err := readMigratorState(versionsFile)
+	if err != nil {
+		return errors.Wrap(err, "reading migrations state failed")
+	}
+
+	pending := m.pendingMigrations(state.Version)
+	for i, migration := range pending {
+		if err = m.runMigration(storageDir, migration); err != nil {
+			return errors.Wrapf(err, "migration from %s to %s failed", migration.FromVersion, migration.ToVersion)
+		}
+
+		if m.OnMigrationProgress != nil {
+			m.OnMigrationProgress(i+1, len(pending))
+		}
+	}
+	return nil
+}
+
+// pendingMigrations returns, in order, the registered migrations still
+// needed to bring a storage directory currently at version up to date. An
+// empty version runs every registered migration.
+func (m *Migrator) pendingMigrations(version string) []Migration {
+	var pending []Migration
+
+	applying := len(version) == 0
+	for _, migration := range m.migrations {
+		if !applying && migration.FromVersion == version {
+			applying = true
+		}
+		if applying {
+			pending = append(pending, migration)
+		}
+	}
+	return pending
+}
+
+// runMigration stages storageDir into a sibling "storage.new" directory,
+// runs migration.Up against the staged copy, records the resulting
+// version inside that same staged copy, and swaps it in for storageDir
+// only once all of that succeeds. Folding the version bump into the
+// staged directory means the content swap and the version record move
+// together in the single os.Rename below, so a crash can never leave
+// storageDir migrated but still recorded at the old version (which would
+// otherwise make the next Run replay Up against already-migrated data).
+func (m *Migrator) runMigration(storageDir string, migration Migration) error {
+	staged := storageDir + ".new"
+	backup := storageDir + ".bak"
+
+	if err := os.RemoveAll(staged); err != nil {
+		return err
+	}
+	if err := copyDir(storageDir, staged); err != nil {
+		return err
+	}
+
+	if err := migration.Up(staged); err != nil {
+		os.RemoveAll(staged)
+		return err
+	}
+
+	state := migratorState{Version: migration.ToVersion}
+	if err := writeMigratorState(filepath.Join(staged, migrationsFilename), state); err != nil {
+		os.RemoveAll(staged)
+		return err
+	}
+
+	if err := os.RemoveAll(backup); err != nil {
+		return err
+	}
+	if _, err := os.Stat(storageDir); err == nil {
+		if err = os.Rename(storageDir, backup); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(staged, storageDir); err != nil {
+		os.Rename(backup, storageDir)
+		return err
+	}
+	return os.RemoveAll(backup)
+}
+
+func readMigratorState(filename string) (migratorState, error) {
+	b, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return migratorState{}, nil
+	} else if err != nil {
+		return migratorState{}, err
+	}
+
+	var state migratorState
+	err = json.Unmarshal(b, &state)
+	return state, err
+}
+
+// writeMigratorState records state atomically: it is written to a
+// temporary file in the same directory as filename first, then swapped in
+// with os.Rename, so a crash mid-write cannot leave filename truncated or
+// corrupt.
+func writeMigratorState(filename string, state migratorState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+
+	staged := filename + ".new"
+	if err = ioutil.WriteFile(staged, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(staged, filename)
+}
+
+// copyDir recursively copies src into dst. If src does not exist yet, dst is
+// created as an empty directory.
+func copyDir(src, dst string) error {
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return os.MkdirAll(dst, 0755)
+	}
+
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode())
+		}
+		return copyFile(path, target, info.Mode())
+	})
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err = os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, in)
+	return err
+}
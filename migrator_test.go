@@ -0,0 +1,173 @@
+package app
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigratorPendingMigrationsFromEmptyVersion(t *testing.T) {
+	m := &Migrator{}
+	m.Register(Migration{FromVersion: "", ToVersion: "1"})
+	m.Register(Migration{FromVersion: "1", ToVersion: "2"})
+	m.Register(Migration{FromVersion: "2", ToVersion: "3"})
+
+	pending := m.pendingMigrations("")
+	if len(pending) != 3 {
+		t.Fatalf("expected 3 pending migrations, got %d", len(pending))
+	}
+}
+
+func TestMigratorPendingMigrationsFromMiddleVersion(t *testing.T) {
+	m := &Migrator{}
+	m.Register(Migration{FromVersion: "", ToVersion: "1"})
+	m.Register(Migration{FromVersion: "1", ToVersion: "2"})
+	m.Register(Migration{FromVersion: "2", ToVersion: "3"})
+
+	pending := m.pendingMigrations("1")
+	if len(pending) != 2 {
+		t.Fatalf("expected 2 pending migrations, got %d", len(pending))
+	}
+	if pending[0].FromVersion != "1" || pending[1].FromVersion != "2" {
+		t.Fatalf("unexpected pending migrations: %+v", pending)
+	}
+}
+
+func TestMigratorPendingMigrationsUpToDate(t *testing.T) {
+	m := &Migrator{}
+	m.Register(Migration{FromVersion: "", ToVersion: "1"})
+
+	pending := m.pendingMigrations("1")
+	if len(pending) != 0 {
+		t.Fatalf("expected no pending migrations, got %d", len(pending))
+	}
+}
+
+func TestMigratorRunAppliesPendingMigrationsAndRecordsVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "app-migrator-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	storageDir := filepath.Join(dir, "storage")
+	if err = os.MkdirAll(storageDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Migrator{}
+	var progress []int
+	m.OnMigrationProgress = func(step, total int) {
+		progress = append(progress, step)
+	}
+	m.Register(Migration{
+		FromVersion: "",
+		ToVersion:   "1",
+		Up: func(storageDir string) error {
+			return ioutil.WriteFile(filepath.Join(storageDir, "marker"), []byte("1"), 0644)
+		},
+	})
+
+	if err = m.Run(storageDir); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = os.Stat(filepath.Join(storageDir, "marker")); err != nil {
+		t.Fatalf("expected migration to have run: %v", err)
+	}
+
+	state, err := readMigratorState(filepath.Join(storageDir, migrationsFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Version != "1" {
+		t.Fatalf("expected recorded version %q, got %q", "1", state.Version)
+	}
+	if len(progress) != 1 || progress[0] != 1 {
+		t.Fatalf("expected one progress callback for step 1, got %v", progress)
+	}
+
+	ran := false
+	m.Register(Migration{
+		FromVersion: "1",
+		ToVersion:   "2",
+		Up: func(storageDir string) error {
+			ran = true
+			return nil
+		},
+	})
+	if err = m.Run(storageDir); err != nil {
+		t.Fatal(err)
+	}
+	if !ran {
+		t.Fatal("expected the newly registered migration to run on the next Run")
+	}
+}
+
+func TestMigratorRunRollsBackOnFailedMigration(t *testing.T) {
+	dir, err := ioutil.TempDir("", "app-migrator-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	storageDir := filepath.Join(dir, "storage")
+	if err = os.MkdirAll(storageDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(storageDir, "existing"), []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Migrator{}
+	m.Register(Migration{
+		FromVersion: "",
+		ToVersion:   "1",
+		Up: func(storageDir string) error {
+			return errors.New("boom")
+		},
+	})
+
+	if err = m.Run(storageDir); err == nil {
+		t.Fatal("expected Run to fail")
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(storageDir, "existing"))
+	if err != nil {
+		t.Fatalf("expected storage directory to be left untouched: %v", err)
+	}
+	if string(b) != "keep" {
+		t.Fatalf("unexpected content: %q", b)
+	}
+
+	if _, err = os.Stat(filepath.Join(storageDir, migrationsFilename)); !os.IsNotExist(err) {
+		t.Fatal("expected no version to be recorded after a failed migration")
+	}
+}
+
+func TestWriteMigratorStateIsAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "app-migrator-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, migrationsFilename)
+	if err = writeMigratorState(filename, migratorState{Version: "1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = os.Stat(filename + ".new"); !os.IsNotExist(err) {
+		t.Fatal("expected the staged file to be gone once writeMigratorState returns")
+	}
+
+	state, err := readMigratorState(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Version != "1" {
+		t.Fatalf("expected version %q, got %q", "1", state.Version)
+	}
+}
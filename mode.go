@@ -0,0 +1,105 @@
+package app
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Mode is a runtime mode a Driver can mount its component tree in.
+type Mode int
+
+// Constants enumerating the runtime modes supported by ModeFactory.
+const (
+	// ModePublic is the mode for a "guest" UI meant to be shown to anyone.
+	ModePublic Mode = iota
+
+	// ModePrivate is the mode for an authenticated, user-specific UI.
+	ModePrivate
+)
+
+type componentTag int
+
+const (
+	tagNone componentTag = iota
+	tagPrivateOnly
+	tagPublicOnly
+)
+
+// ModeFactory is a Factory that redirects components tagged PrivateOnly or
+// PublicOnly to a fallback URL when requested in the wrong mode.
+type ModeFactory struct {
+	Factory
+
+	mode     func() Mode
+	fallback string
+
+	tagsMutex sync.Mutex
+	tags      map[string]componentTag
+}
+
+// NewModeFactory returns a Factory wrapping f. mode is called on every New
+// to determine the driver's current mode. fallback is the URL whose
+// component is created in place of one restricted to the wrong mode.
+func NewModeFactory(f Factory, mode func() Mode, fallback string) *ModeFactory {
+	return &ModeFactory{
+		Factory:  f,
+		mode:     mode,
+		fallback: fallback,
+		tags:     make(map[string]componentTag),
+	}
+}
+
+// PrivateOnly tags name so it is only ever mounted while the driver runs
+// in ModePrivate.
+func (f *ModeFactory) PrivateOnly(name string) {
+	f.setTag(name, tagPrivateOnly)
+}
+
+// PublicOnly tags name so it is only ever mounted while the driver runs in
+// ModePublic.
+func (f *ModeFactory) PublicOnly(name string) {
+	f.setTag(name, tagPublicOnly)
+}
+
+func (f *ModeFactory) setTag(name string, tag componentTag) {
+	f.tagsMutex.Lock()
+	f.tags[strings.ToLower(name)] = tag
+	f.tagsMutex.Unlock()
+}
+
+func (f *ModeFactory) tagFor(name string) componentTag {
+	f.tagsMutex.Lock()
+	defer f.tagsMutex.Unlock()
+	return f.tags[strings.ToLower(name)]
+}
+
+// New satisfies the Factory interface. It creates name's component, unless
+// name is tagged PrivateOnly or PublicOnly and the current mode does not
+// match, in which case the fallback URL's component is created instead,
+// without ever constructing the restricted component.
+func (f *ModeFactory) New(name string) (Component, error) {
+	mode := f.mode()
+
+	switch f.tagFor(name) {
+	case tagPrivateOnly:
+		if mode != ModePrivate {
+			return f.fallbackComponent()
+		}
+	case tagPublicOnly:
+		if mode != ModePublic {
+			return f.fallbackComponent()
+		}
+	}
+	return f.Factory.New(name)
+}
+
+func (f *ModeFactory) fallbackComponent() (Component, error) {
+	u, err := url.Parse(f.fallback)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing mode fallback url failed")
+	}
+	return f.Factory.New(ComponentNameFromURL(u))
+}
@@ -0,0 +1,76 @@
+package app
+
+import "testing"
+
+// countingFactory records, in order, every name it was asked to construct.
+type countingFactory struct {
+	calls []string
+}
+
+func (f *countingFactory) New(name string) (Component, error) {
+	f.calls = append(f.calls, name)
+	return &RawContent{HTML: name}, nil
+}
+
+func TestModeFactoryAllowsUntaggedComponents(t *testing.T) {
+	f := &countingFactory{}
+	mf := NewModeFactory(f, func() Mode { return ModePublic }, "component://fallback")
+
+	if _, err := mf.New("home"); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.calls) != 1 || f.calls[0] != "home" {
+		t.Fatalf("expected home to be constructed once, got %v", f.calls)
+	}
+}
+
+func TestModeFactoryRedirectsPrivateOnlyComponentInPublicMode(t *testing.T) {
+	f := &countingFactory{}
+	mf := NewModeFactory(f, func() Mode { return ModePublic }, "component://fallback")
+	mf.PrivateOnly("settings")
+
+	if _, err := mf.New("settings"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range f.calls {
+		if name == "settings" {
+			t.Fatalf("settings should never have been constructed in public mode, calls: %v", f.calls)
+		}
+	}
+	if len(f.calls) != 1 {
+		t.Fatalf("expected exactly one fallback construction, got %v", f.calls)
+	}
+}
+
+func TestModeFactoryAllowsPrivateOnlyComponentInPrivateMode(t *testing.T) {
+	f := &countingFactory{}
+	mf := NewModeFactory(f, func() Mode { return ModePrivate }, "component://fallback")
+	mf.PrivateOnly("settings")
+
+	if _, err := mf.New("settings"); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.calls) != 1 || f.calls[0] != "settings" {
+		t.Fatalf("expected settings to be constructed once, got %v", f.calls)
+	}
+}
+
+func TestModeFactoryRedirectsPublicOnlyComponentInPrivateMode(t *testing.T) {
+	f := &countingFactory{}
+	mf := NewModeFactory(f, func() Mode { return ModePrivate }, "component://fallback")
+	mf.PublicOnly("welcome")
+
+	if _, err := mf.New("welcome"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range f.calls {
+		if name == "welcome" {
+			t.Fatalf("welcome should never have been constructed in private mode, calls: %v", f.calls)
+		}
+	}
+	if len(f.calls) != 1 {
+		t.Fatalf("expected exactly one fallback construction, got %v", f.calls)
+	}
+}
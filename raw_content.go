@@ -0,0 +1,28 @@
+package app
+
+// RawContent is the Component mounted for a URL resolved through a
+// ContentLoader (file, https, gemini...) rather than a driver's Factory.
+// It renders the fetched content as-is, already converted to HTML if
+// necessary.
+type RawContent struct {
+	HTML string
+}
+
+// Render satisfies the Component interface.
+func (c *RawContent) Render() string {
+	return c.HTML
+}
+
+// StaticFactory is a Factory whose New method always returns Component,
+// regardless of the requested name. It lets a single pre-fetched
+// Component, such as one produced from a ContentLoader, be mounted through
+// the same Factory-based loading path as a driver's registered
+// components.
+type StaticFactory struct {
+	Component Component
+}
+
+// New satisfies the Factory interface.
+func (f StaticFactory) New(name string) (Component, error) {
+	return f.Component, nil
+}